@@ -0,0 +1,289 @@
+package packedancestrymap
+
+import (
+	"bufio"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Chi2Options controls Chi2Scan's filtering and test mode.
+type Chi2Options struct {
+	// Allelic collapses the 2x3 genotype table into a 2x2 allele-count
+	// table (df=1) instead of the default 2x3 genotype table (df=2).
+	Allelic bool
+	// MaxPValue drops SNPs whose p-value is above this threshold. Zero
+	// means no filtering.
+	MaxPValue float64
+	// MinCoverage drops SNPs where fewer than this many samples (cases
+	// plus controls) have a non-missing genotype.
+	MinCoverage int
+}
+
+// Chi2Result is the outcome of the case/control test for a single SNP.
+type Chi2Result struct {
+	Snp      Snp
+	ChiSq    float64
+	PValue   float64
+	DF       int
+	Coverage int
+}
+
+// LoadCases reads a two-column TSV of sample ID -> 0/1 and resolves it
+// against inds, returning a per-individual boolean slice suitable for
+// Chi2Scan. Sample IDs absent from the TSV are treated as not a case
+// (false), matching a control/unknown default.
+func LoadCases(path string, inds []Ind) ([]bool, error) {
+	byID := make(map[string]bool, len(inds))
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		isCase, err := strconv.ParseBool(fields[1])
+		if err != nil {
+			v, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			isCase = v != 0
+		}
+		byID[fields[0]] = isCase
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	cases := make([]bool, len(inds))
+	for i, ind := range inds {
+		cases[i] = byID[ind.SampleID]
+	}
+
+	return cases, nil
+}
+
+// CasesByLabel builds a Chi2Scan cases slice by marking every individual
+// whose .ind label column equals caseLabel as a case.
+func CasesByLabel(inds []Ind, caseLabel string) []bool {
+	cases := make([]bool, len(inds))
+	for i, ind := range inds {
+		cases[i] = ind.Label == caseLabel
+	}
+	return cases
+}
+
+// Chi2Scan streams genoPath/indPath/snpPath through ProcessGenoRowsSeq, in
+// genome (file) order, and for each SNP builds a contingency table of
+// genotype counts (or allele counts in opts.Allelic mode) split by cases vs.
+// controls, computing the Pearson chi-square statistic and its p-value
+// (df=2, or df=1 when Allelic). len(cases) must equal the number of
+// individuals in indPath. Results are returned in the same order, so callers
+// can sort/plot by position without an extra pass.
+func Chi2Scan(genoPath, indPath, snpPath string, cases []bool, opts Chi2Options) ([]Chi2Result, error) {
+	inds, err := ReadIndFile(indPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(cases) != len(inds) {
+		return nil, errNoCases
+	}
+
+	var results []Chi2Result
+
+	err = ProcessGenoRowsSeq(genoPath, indPath, snpPath, func(genoRow []byte, snp Snp, inds []Ind) error {
+		var table [2][3]float64 // rows: control/case, cols: genotype 0/1/2
+		coverage := 0
+		for i, g := range genoRow {
+			if g == 3 {
+				continue
+			}
+			row := 0
+			if cases[i] {
+				row = 1
+			}
+			table[row][g]++
+			coverage++
+		}
+
+		if coverage < opts.MinCoverage {
+			return nil
+		}
+
+		var chiSq float64
+		df := 2
+		if opts.Allelic {
+			chiSq, df = allelicChiSq(table)
+		} else {
+			chiSq, df = genotypeChiSq(table)
+		}
+
+		pValue := chiSqSurvival(chiSq, df)
+		if opts.MaxPValue > 0 && pValue > opts.MaxPValue {
+			return nil
+		}
+
+		results = append(results, Chi2Result{
+			Snp:      snp,
+			ChiSq:    chiSq,
+			PValue:   pValue,
+			DF:       df,
+			Coverage: coverage,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// genotypeChiSq computes the Pearson chi-square statistic for a 2x3
+// control/case by genotype(0/1/2) table, skipping cells whose expected
+// count is zero.
+func genotypeChiSq(table [2][3]float64) (float64, int) {
+	rowTotal := [2]float64{}
+	colTotal := [3]float64{}
+	grandTotal := 0.0
+	for r := 0; r < 2; r++ {
+		for c := 0; c < 3; c++ {
+			rowTotal[r] += table[r][c]
+			colTotal[c] += table[r][c]
+			grandTotal += table[r][c]
+		}
+	}
+
+	if grandTotal == 0 {
+		return 0, 2
+	}
+
+	chiSq := 0.0
+	for r := 0; r < 2; r++ {
+		for c := 0; c < 3; c++ {
+			expected := rowTotal[r] * colTotal[c] / grandTotal
+			if expected == 0 {
+				continue
+			}
+			diff := table[r][c] - expected
+			chiSq += diff * diff / expected
+		}
+	}
+
+	return chiSq, 2
+}
+
+// allelicChiSq collapses the genotype table into a 2x2 allele-count table
+// (each genotype 0/1/2 contributes 0/1/2 alt alleles out of 2 per sample)
+// and computes the chi-square statistic with df=1.
+func allelicChiSq(table [2][3]float64) (float64, int) {
+	var alleles [2][2]float64 // rows: control/case, cols: ref/alt allele count
+	for r := 0; r < 2; r++ {
+		for g := 0; g < 3; g++ {
+			n := table[r][g]
+			alleles[r][0] += n * float64(2-g)
+			alleles[r][1] += n * float64(g)
+		}
+	}
+
+	rowTotal := [2]float64{alleles[0][0] + alleles[0][1], alleles[1][0] + alleles[1][1]}
+	colTotal := [2]float64{alleles[0][0] + alleles[1][0], alleles[0][1] + alleles[1][1]}
+	grandTotal := rowTotal[0] + rowTotal[1]
+
+	if grandTotal == 0 {
+		return 0, 1
+	}
+
+	chiSq := 0.0
+	for r := 0; r < 2; r++ {
+		for c := 0; c < 2; c++ {
+			expected := rowTotal[r] * colTotal[c] / grandTotal
+			if expected == 0 {
+				continue
+			}
+			diff := alleles[r][c] - expected
+			chiSq += diff * diff / expected
+		}
+	}
+
+	return chiSq, 1
+}
+
+// chiSqSurvival returns P(X > chiSq) for a chi-square distribution with df
+// degrees of freedom, i.e. 1 - CDF. It is computed via the regularized upper
+// incomplete gamma function Q(df/2, chiSq/2), which stays numerically stable
+// (and accurate well below 1e-30) far into the tail where a naive CDF
+// subtraction would lose all precision to cancellation.
+func chiSqSurvival(chiSq float64, df int) float64 {
+	if chiSq <= 0 {
+		return 1
+	}
+	return upperIncompleteGammaQ(float64(df)/2, chiSq/2)
+}
+
+// upperIncompleteGammaQ computes the regularized upper incomplete gamma
+// function Q(a, x) = Gamma(a, x) / Gamma(a). For x < a+1 it sums the
+// complementary series for P(a, x) = 1 - Q(a, x); for x >= a+1 it evaluates
+// Q(a, x) directly via its continued fraction (Lentz's algorithm), which is
+// the numerically stable branch in the tail.
+func upperIncompleteGammaQ(a, x float64) float64 {
+	const (
+		maxIter = 500
+		eps     = 1e-16
+		tiny    = 1e-300
+	)
+
+	if x < a+1 {
+		// Series for P(a, x), then Q = 1 - P.
+		sum := 1 / a
+		term := sum
+		for n := 1; n < maxIter; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*eps {
+				break
+			}
+		}
+		logGamma, _ := math.Lgamma(a)
+		p := sum * math.Exp(-x+a*math.Log(x)-logGamma)
+		return 1 - p
+	}
+
+	// Continued fraction for Q(a, x) (Lentz's algorithm).
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < maxIter; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+
+	logGamma, _ := math.Lgamma(a)
+	return h * math.Exp(-x+a*math.Log(x)-logGamma)
+}
+
+var errNoCases = errors.New("cases slice does not match number of individuals")
@@ -0,0 +1,155 @@
+package packedancestrymap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCases(t *testing.T) {
+	inds := []Ind{
+		{SampleID: "S1", Gender: "M", Label: "Pop1"},
+		{SampleID: "S2", Gender: "F", Label: "Pop1"},
+		{SampleID: "S3", Gender: "U", Label: "Pop1"},
+		{SampleID: "S4", Gender: "U", Label: "Pop1"},
+	}
+
+	// Mixes strconv.ParseBool-style values with the integer Atoi fallback,
+	// and leaves S4 out entirely to exercise the not-listed -> false default.
+	tsv := "S1\t2\nS2\ttrue\nS3\t0\n"
+	path := filepath.Join(t.TempDir(), "cases.tsv")
+	if err := os.WriteFile(path, []byte(tsv), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases, err := LoadCases(path, inds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []bool{true, true, false, false}
+	for i := range want {
+		if cases[i] != want[i] {
+			t.Errorf("cases[%d] = %v, want %v", i, cases[i], want[i])
+		}
+	}
+}
+
+func TestCasesByLabel(t *testing.T) {
+	inds := []Ind{
+		{SampleID: "S1", Label: "Case"},
+		{SampleID: "S2", Label: "Control"},
+		{SampleID: "S3", Label: "Case"},
+	}
+
+	got := CasesByLabel(inds, "Case")
+	want := []bool{true, false, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("CasesByLabel[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestChi2Scan(t *testing.T) {
+	dir := t.TempDir()
+	geno := filepath.Join(dir, "c.geno")
+	ind := filepath.Join(dir, "c.ind")
+	snp := filepath.Join(dir, "c.snp")
+
+	inds := []Ind{
+		{SampleID: "Case1", Gender: "U", Label: "Case"},
+		{SampleID: "Case2", Gender: "U", Label: "Case"},
+		{SampleID: "Case3", Gender: "U", Label: "Case"},
+		{SampleID: "Ctrl1", Gender: "U", Label: "Control"},
+		{SampleID: "Ctrl2", Gender: "U", Label: "Control"},
+		{SampleID: "Ctrl3", Gender: "U", Label: "Control"},
+	}
+	snps := []Snp{
+		// Perfectly separates cases (hom-ref) from controls (hom-alt).
+		{Name: "rsAssociated", Chromosome: 1, PhysicalPos: 1000, Ref: 'A', Alt: 'G'},
+		// Identical distribution between cases and controls.
+		{Name: "rsNull", Chromosome: 1, PhysicalPos: 2000, Ref: 'A', Alt: 'G'},
+		// Only one non-missing call: fails a MinCoverage filter.
+		{Name: "rsLowCoverage", Chromosome: 1, PhysicalPos: 3000, Ref: 'A', Alt: 'G'},
+	}
+	rows := [][]byte{
+		{0, 0, 0, 2, 2, 2},
+		{0, 1, 2, 0, 1, 2},
+		{0, 3, 3, 3, 3, 3},
+	}
+
+	w, err := NewWriter(geno, ind, snp, inds, snps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.WriteGenoRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := CasesByLabel(inds, "Case")
+
+	t.Run("unfiltered", func(t *testing.T) {
+		results, err := Chi2Scan(geno, ind, snp, cases, Chi2Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 3 {
+			t.Fatalf("got %d results, want 3", len(results))
+		}
+
+		if results[0].Snp.Name != "rsAssociated" || results[0].DF != 2 || results[0].Coverage != 6 {
+			t.Errorf("rsAssociated result = %+v", results[0])
+		}
+		if results[0].PValue >= 0.05 {
+			t.Errorf("rsAssociated PValue = %v, want a small p-value for a perfectly separating SNP", results[0].PValue)
+		}
+
+		if results[1].Snp.Name != "rsNull" || results[1].ChiSq > 1e-9 {
+			t.Errorf("rsNull result = %+v, want chiSq ~0", results[1])
+		}
+		if results[1].PValue < 0.99 {
+			t.Errorf("rsNull PValue = %v, want ~1 for identical case/control distributions", results[1].PValue)
+		}
+
+		if results[2].Snp.Name != "rsLowCoverage" || results[2].Coverage != 1 {
+			t.Errorf("rsLowCoverage result = %+v, want coverage 1", results[2])
+		}
+	})
+
+	t.Run("MinCoverage filters low-coverage SNPs", func(t *testing.T) {
+		results, err := Chi2Scan(geno, ind, snp, cases, Chi2Options{MinCoverage: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range results {
+			if r.Snp.Name == "rsLowCoverage" {
+				t.Errorf("rsLowCoverage should have been dropped by MinCoverage, got %+v", r)
+			}
+		}
+		if len(results) != 2 {
+			t.Fatalf("got %d results, want 2", len(results))
+		}
+	})
+
+	t.Run("MaxPValue filters non-significant SNPs", func(t *testing.T) {
+		results, err := Chi2Scan(geno, ind, snp, cases, Chi2Options{MaxPValue: 0.05})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0].Snp.Name != "rsAssociated" {
+			t.Fatalf("got %+v, want only rsAssociated", results)
+		}
+	})
+
+	t.Run("mismatched cases length errors", func(t *testing.T) {
+		if _, err := Chi2Scan(geno, ind, snp, cases[:len(cases)-1], Chi2Options{}); err == nil {
+			t.Error("expected an error for a cases slice shorter than the individual count")
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package packedancestrymap
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChiSqSurvival(t *testing.T) {
+	cases := []struct {
+		chiSq, df, want float64
+	}{
+		{0, 2, 1},
+		{3.84, 1, 0.05},
+		{5.99, 2, 0.05},
+	}
+
+	for _, c := range cases {
+		got := chiSqSurvival(c.chiSq, int(c.df))
+		if math.Abs(got-c.want) > 1e-2 {
+			t.Errorf("chiSqSurvival(%v, %v) = %v, want ~%v", c.chiSq, c.df, got, c.want)
+		}
+	}
+}
+
+func TestGenotypeChiSq(t *testing.T) {
+	// Identical genotype distribution between cases and controls should
+	// yield a chi-square statistic of (approximately) zero.
+	table := [2][3]float64{
+		{10, 20, 10},
+		{10, 20, 10},
+	}
+
+	chiSq, df := genotypeChiSq(table)
+	if df != 2 {
+		t.Errorf("df = %d, want 2", df)
+	}
+	if chiSq > 1e-9 {
+		t.Errorf("chiSq = %v, want ~0", chiSq)
+	}
+}
@@ -0,0 +1,200 @@
+package packedancestrymap
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ExportOptions controls the layout of the matrix produced by ExportNumpy.
+type ExportOptions struct {
+	// Transpose writes SNPs as rows and individuals as columns. The
+	// default (false) writes one row per individual and one column per SNP.
+	Transpose bool
+	// MissingValue replaces the packed missing genotype (3) in the
+	// output matrix. Defaults to 9 when left at zero.
+	MissingValue int8
+}
+
+// npyHeader returns the NumPy v1.0 header for a 2-D int8 array of the given
+// shape, padded so the data begins on a 16-byte boundary as the format requires.
+func npyHeader(rows, cols int) []byte {
+	dict := fmt.Sprintf("{'descr': '|i1', 'fortran_order': False, 'shape': (%d, %d), }", rows, cols)
+
+	// magic(6) + version(2) + header length(2) = 10 bytes before the dict.
+	padded := len(dict) + 1 // trailing newline
+	total := 10 + padded
+	if rem := total % 16; rem != 0 {
+		padded += 16 - rem
+	}
+	dict += strings.Repeat(" ", padded-len(dict)-1) + "\n"
+
+	header := make([]byte, 0, 10+len(dict))
+	header = append(header, 0x93, 'N', 'U', 'M', 'P', 'Y', 1, 0)
+	header = append(header, byte(len(dict)), byte(len(dict)>>8))
+	header = append(header, dict...)
+
+	return header
+}
+
+// ExportNumpy streams the decoded genotype matrix for genoPath/indPath/snpPath
+// into a NumPy .npy file at outPath, encoding genotypes as 0/1/2 copies of the
+// alt allele and mapping the packed missing value (3) to opts.MissingValue. A
+// companion annotations.csv is written next to outPath listing, for every SNP,
+// its name, chromosome, physical position and ref/alt alleles, so downstream
+// tooling can consume the matrix without re-parsing the .snp file.
+//
+// The matrix is written as ProcessGenoRowsSeq decodes each SNP row. With
+// Transpose each row is written as it arrives; otherwise columns are buffered
+// in blocks of at most numpyExportBlockBytes, so memory use stays bounded by
+// one block rather than the full individuals x SNPs matrix.
+func ExportNumpy(genoPath, indPath, snpPath, outPath string, opts ExportOptions) error {
+	missing := opts.MissingValue
+	if missing == 0 {
+		missing = 9
+	}
+
+	snps, err := ReadSnpFile(snpPath)
+	if err != nil {
+		return err
+	}
+	inds, err := ReadIndFile(indPath)
+	if err != nil {
+		return err
+	}
+
+	if err := writeAnnotations(filepath.Join(filepath.Dir(outPath), "annotations.csv"), snps); err != nil {
+		return err
+	}
+
+	nRows, nCols := len(inds), len(snps)
+	if opts.Transpose {
+		nRows, nCols = nCols, nRows
+	}
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := out.Write(npyHeader(nRows, nCols)); err != nil {
+		return err
+	}
+	dataOffset, err := out.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	if opts.Transpose {
+		// SNPs are rows: each decoded genoRow is one row, write it sequentially.
+		return ProcessGenoRowsSeq(genoPath, indPath, snpPath, func(genoRow []byte, snp Snp, inds []Ind) error {
+			row := encodeGenotypeRow(genoRow, missing)
+			_, err := out.Write(row)
+			return err
+		})
+	}
+
+	// Individuals are rows: each decoded genoRow is a column. Buffer a
+	// block of columns across all individuals and flush it with one
+	// WriteAt per individual, rather than one WriteAt per matrix cell,
+	// while still bounding memory to a block instead of the full matrix.
+	blockCols := nCols
+	if nRows > 0 {
+		if bc := numpyExportBlockBytes / nRows; bc < blockCols {
+			if bc < 1 {
+				bc = 1
+			}
+			blockCols = bc
+		}
+	}
+
+	block := make([]byte, nRows*blockCols)
+	col := 0
+	snpIndex := 0
+
+	flush := func() error {
+		if col == 0 {
+			return nil
+		}
+		rowStartCol := snpIndex - col
+		for indIndex := 0; indIndex < nRows; indIndex++ {
+			offset := dataOffset + int64(indIndex*nCols+rowStartCol)
+			segment := block[indIndex*blockCols : indIndex*blockCols+col]
+			if _, err := out.WriteAt(segment, offset); err != nil {
+				return err
+			}
+		}
+		col = 0
+		return nil
+	}
+
+	err = ProcessGenoRowsSeq(genoPath, indPath, snpPath, func(genoRow []byte, snp Snp, inds []Ind) error {
+		row := encodeGenotypeRow(genoRow, missing)
+		for indIndex, v := range row {
+			block[indIndex*blockCols+col] = v
+		}
+		col++
+		snpIndex++
+		if col == blockCols {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// numpyExportBlockBytes bounds the column-block buffer ExportNumpy uses when
+// writing the individuals-as-rows layout: large enough to turn millions of
+// single-byte WriteAt calls into a handful of block writes, small enough to
+// stay well clear of materializing the whole matrix in RAM.
+const numpyExportBlockBytes = 64 << 20
+
+func encodeGenotypeRow(genoRow []byte, missing int8) []byte {
+	row := make([]byte, len(genoRow))
+	for i, g := range genoRow {
+		if g == 3 {
+			row[i] = byte(missing)
+		} else {
+			row[i] = g
+		}
+	}
+	return row
+}
+
+func writeAnnotations(path string, snps []Snp) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	defer w.Flush()
+
+	if err := w.Write([]string{"name", "chromosome", "position", "ref", "alt"}); err != nil {
+		return err
+	}
+	for _, snp := range snps {
+		record := []string{
+			snp.Name,
+			strconv.Itoa(int(snp.Chromosome)),
+			strconv.FormatUint(uint64(snp.PhysicalPos), 10),
+			string(rune(snp.Ref)),
+			string(rune(snp.Alt)),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return w.Error()
+}
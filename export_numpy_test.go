@@ -0,0 +1,176 @@
+package packedancestrymap
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// writeExportTestTriple builds a minimal hash-valid triple via Writer, for
+// exercising ExportNumpy without real EIGENSTRAT fixtures.
+func writeExportTestTriple(t *testing.T, dir string, inds []Ind, snps []Snp, rows [][]byte) (geno, ind, snp string) {
+	t.Helper()
+
+	geno = filepath.Join(dir, "e.geno")
+	ind = filepath.Join(dir, "e.ind")
+	snp = filepath.Join(dir, "e.snp")
+
+	w, err := NewWriter(geno, ind, snp, inds, snps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.WriteGenoRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return geno, ind, snp
+}
+
+// readNpyInt8 parses a minimal NumPy v1.0 int8 .npy file back into its shape
+// and raw bytes, just enough to check what ExportNumpy wrote.
+func readNpyInt8(t *testing.T, path string) (rows, cols int, data []byte) {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	magic := make([]byte, 8)
+	if _, err := r.Read(magic); err != nil {
+		t.Fatal(err)
+	}
+
+	lenBytes := make([]byte, 2)
+	if _, err := r.Read(lenBytes); err != nil {
+		t.Fatal(err)
+	}
+	headerLen := int(lenBytes[0]) | int(lenBytes[1])<<8
+
+	dict := make([]byte, headerLen)
+	if _, err := r.Read(dict); err != nil {
+		t.Fatal(err)
+	}
+
+	shapeStart := indexOf(string(dict), "(") + 1
+	shapeEnd := indexOf(string(dict), ")")
+	parts := splitShape(string(dict)[shapeStart:shapeEnd])
+	rows, err = strconv.Atoi(parts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols, err = strconv.Atoi(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data = make([]byte, rows*cols)
+	if _, err := r.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	return rows, cols, data
+}
+
+func indexOf(s, sub string) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if s[i:i+len(sub)] == sub {
+			return i
+		}
+	}
+	return -1
+}
+
+func splitShape(s string) []string {
+	var parts []string
+	cur := ""
+	for _, c := range s {
+		if c == ',' {
+			parts = append(parts, trimSpace(cur))
+			cur = ""
+			continue
+		}
+		cur += string(c)
+	}
+	if trimSpace(cur) != "" {
+		parts = append(parts, trimSpace(cur))
+	}
+	return parts
+}
+
+func trimSpace(s string) string {
+	start, end := 0, len(s)
+	for start < end && s[start] == ' ' {
+		start++
+	}
+	for end > start && s[end-1] == ' ' {
+		end--
+	}
+	return s[start:end]
+}
+
+func TestExportNumpy(t *testing.T) {
+	dir := t.TempDir()
+	inds := []Ind{
+		{SampleID: "S1", Gender: "M", Label: "Pop1"},
+		{SampleID: "S2", Gender: "F", Label: "Pop2"},
+	}
+	snps := []Snp{
+		{Name: "rs1", Chromosome: 1, PhysicalPos: 1000, Ref: 'A', Alt: 'G'},
+		{Name: "rs2", Chromosome: 1, PhysicalPos: 2000, Ref: 'C', Alt: 'T'},
+		{Name: "rs3", Chromosome: 2, PhysicalPos: 3000, Ref: 'A', Alt: 'C'},
+	}
+	rows := [][]byte{
+		{0, 1},
+		{2, 3}, // second individual missing at rs2
+		{1, 2},
+	}
+	geno, ind, snp := writeExportTestTriple(t, dir, inds, snps, rows)
+
+	t.Run("individuals as rows", func(t *testing.T) {
+		outPath := filepath.Join(dir, "matrix.npy")
+		if err := ExportNumpy(geno, ind, snp, outPath, ExportOptions{}); err != nil {
+			t.Fatal(err)
+		}
+
+		gotRows, gotCols, data := readNpyInt8(t, outPath)
+		if gotRows != len(inds) || gotCols != len(snps) {
+			t.Fatalf("shape = (%d, %d), want (%d, %d)", gotRows, gotCols, len(inds), len(snps))
+		}
+
+		want := []byte{0, 2, 1, 1, 9, 2} // row0 (S1): rs1,rs2,rs3 ; row1 (S2): rs1, missing->9, rs3
+		if !bytesEqual(data, want) {
+			t.Errorf("data = %v, want %v", data, want)
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "annotations.csv")); err != nil {
+			t.Errorf("annotations.csv was not written: %v", err)
+		}
+	})
+
+	t.Run("transpose", func(t *testing.T) {
+		outPath := filepath.Join(dir, "matrix_t.npy")
+		if err := ExportNumpy(geno, ind, snp, outPath, ExportOptions{Transpose: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		gotRows, gotCols, data := readNpyInt8(t, outPath)
+		if gotRows != len(snps) || gotCols != len(inds) {
+			t.Fatalf("shape = (%d, %d), want (%d, %d)", gotRows, gotCols, len(snps), len(inds))
+		}
+
+		want := []byte{0, 1, 2, 9, 1, 2} // row per SNP, cols per individual
+		if !bytesEqual(data, want) {
+			t.Errorf("data = %v, want %v", data, want)
+		}
+	})
+}
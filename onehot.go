@@ -0,0 +1,346 @@
+package packedancestrymap
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// MissingPolicy controls how a missing genotype call is represented in
+// OneHotEncode's output columns.
+type MissingPolicy int
+
+const (
+	// MissingAllZero leaves every column zero for a missing call.
+	MissingAllZero MissingPolicy = iota
+	// MissingAsHet treats a missing call as heterozygous.
+	MissingAsHet
+)
+
+// OneHotEncode expands a decoded SNP genotype row (values 0/1/2/3 as
+// produced by ProcessGenoRows) into per-individual one-hot columns: hom-ref,
+// het, hom-alt, in that order. A missing call (3) is handled per
+// missingPolicy. The returned slice is len(genoRow)*3 long, column-major
+// within each individual's block of 3 (i.e. genoRow[i]'s columns are at
+// [3*i:3*i+3]).
+func OneHotEncode(genoRow []byte, missingPolicy MissingPolicy) []uint8 {
+	out := make([]uint8, len(genoRow)*3)
+	for i, g := range genoRow {
+		class := g
+		if class == 3 {
+			if missingPolicy == MissingAsHet {
+				class = 1
+			} else {
+				continue
+			}
+		}
+		out[3*i+int(class)] = 1
+	}
+	return out
+}
+
+// OneHotAllelicEncode is OneHotEncode's additive/allelic mode: two columns
+// per individual (ref-allele count, alt-allele count) instead of three
+// genotype classes.
+func OneHotAllelicEncode(genoRow []byte, missingPolicy MissingPolicy) []uint8 {
+	out := make([]uint8, len(genoRow)*2)
+	for i, g := range genoRow {
+		if g == 3 {
+			if missingPolicy != MissingAsHet {
+				continue
+			}
+			g = 1
+		}
+		out[2*i] = uint8(2 - g)
+		out[2*i+1] = uint8(g)
+	}
+	return out
+}
+
+// OneHotOptions controls ExportOneHot's column selection.
+type OneHotOptions struct {
+	// Allelic selects the two-column additive encoding instead of the
+	// default three-column genotype-class encoding.
+	Allelic bool
+	// MissingPolicy controls how missing calls are encoded before the
+	// frequency filters below are applied.
+	MissingPolicy MissingPolicy
+	// MinFrequency skips the whole variant when its minor allele
+	// frequency (the lesser of the ref/alt allele frequencies across
+	// non-missing calls) is below this threshold.
+	MinFrequency float64
+	// MaxFrequency skips the whole variant when its minor allele
+	// frequency is above this threshold. Zero means no cap.
+	MaxFrequency float64
+}
+
+// oneHotColumn describes one retained output column's provenance.
+type oneHotColumn struct {
+	SnpName string
+	Class   string // "hom-ref", "het", "hom-alt", "ref-count", "alt-count"
+}
+
+var genotypeClassNames = [3]string{"hom-ref", "het", "hom-alt"}
+var allelicClassNames = [2]string{"ref-count", "alt-count"}
+
+// ExportOneHot streams genoPath/indPath/snpPath through ProcessGenoRowsSeq
+// twice: a first pass decides, per SNP and per genotype class, whether the
+// resulting column clears the frequency filter and isn't all-zero, without
+// holding any column's individual-level data in memory; a second pass
+// streams each retained column straight to outPath. This keeps memory
+// bounded by one column block of at most numpyExportBlockBytes rather than
+// the full individuals x retained-columns matrix, matching ExportNumpy's (chunk0-1) approach for
+// the same real 1240K/HO-scale datasets. Columns are written as a NumPy
+// .npy file when outPath ends in ".npy"; otherwise a compact custom binary
+// is written: a header giving the column count followed by each column's
+// SNP name and class, then the matrix bytes.
+func ExportOneHot(genoPath, indPath, snpPath, outPath string, opts OneHotOptions) error {
+	inds, err := ReadIndFile(indPath)
+	if err != nil {
+		return err
+	}
+	nInds := len(inds)
+
+	classNames := genotypeClassNames[:]
+	colsPerSnp := 3
+	encode := OneHotEncode
+	if opts.Allelic {
+		classNames = allelicClassNames[:]
+		colsPerSnp = 2
+		encode = OneHotAllelicEncode
+	}
+
+	columns, keep, err := planOneHotColumns(genoPath, indPath, snpPath, nInds, colsPerSnp, classNames, encode, opts)
+	if err != nil {
+		return err
+	}
+
+	if isNpyPath(outPath) {
+		return streamOneHotNpy(outPath, genoPath, indPath, snpPath, nInds, len(columns), colsPerSnp, keep, encode, opts.MissingPolicy)
+	}
+	return streamOneHotCustom(outPath, genoPath, indPath, snpPath, nInds, columns, colsPerSnp, keep, encode, opts.MissingPolicy)
+}
+
+// planOneHotColumns is ExportOneHot's first pass: it decides, per SNP and
+// per genotype class, whether that column survives opts' frequency filter
+// and has at least one non-zero entry, returning the retained columns'
+// provenance and a keep[snpIndex][class] matrix the second pass uses to
+// know which columns to re-encode and write.
+func planOneHotColumns(genoPath, indPath, snpPath string, nInds, colsPerSnp int, classNames []string, encode func([]byte, MissingPolicy) []uint8, opts OneHotOptions) ([]oneHotColumn, [][]bool, error) {
+	var columns []oneHotColumn
+	var keep [][]bool
+
+	err := ProcessGenoRowsSeq(genoPath, indPath, snpPath, func(genoRow []byte, snp Snp, inds []Ind) error {
+		snpKeep := make([]bool, colsPerSnp)
+
+		if passesFrequencyFilter(genoRow, opts) {
+			encoded := encode(genoRow, opts.MissingPolicy)
+			for c := 0; c < colsPerSnp; c++ {
+				for i := 0; i < nInds; i++ {
+					if encoded[colsPerSnp*i+c] != 0 {
+						snpKeep[c] = true
+						break
+					}
+				}
+				if snpKeep[c] {
+					columns = append(columns, oneHotColumn{SnpName: snp.Name, Class: classNames[c]})
+				}
+			}
+		}
+
+		keep = append(keep, snpKeep)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return columns, keep, nil
+}
+
+// passesFrequencyFilter decides whether a whole variant clears opts'
+// frequency thresholds, computed once from its minor allele frequency (the
+// lesser of the ref/alt allele frequencies across non-missing calls) rather
+// than per output column, so a low-MAF SNP is skipped entirely instead of
+// just losing its rare-class columns.
+func passesFrequencyFilter(genoRow []byte, opts OneHotOptions) bool {
+	if opts.MinFrequency == 0 && opts.MaxFrequency == 0 {
+		return true
+	}
+
+	refCount, altCount := 0, 0
+	for _, g := range genoRow {
+		if g == 3 {
+			if opts.MissingPolicy == MissingAsHet {
+				refCount++
+				altCount++
+			}
+			continue
+		}
+		refCount += int(2 - g)
+		altCount += int(g)
+	}
+
+	total := refCount + altCount
+	if total == 0 {
+		return opts.MinFrequency == 0
+	}
+
+	minorFreq := float64(refCount) / float64(total)
+	if altFreq := float64(altCount) / float64(total); altFreq < minorFreq {
+		minorFreq = altFreq
+	}
+
+	if minorFreq < opts.MinFrequency {
+		return false
+	}
+	if opts.MaxFrequency > 0 && minorFreq > opts.MaxFrequency {
+		return false
+	}
+
+	return true
+}
+
+func isNpyPath(path string) bool {
+	return len(path) >= 4 && path[len(path)-4:] == ".npy"
+}
+
+// streamOneHotNpy writes the .npy header for an nInds x nCols matrix (int8
+// dtype, via npyHeader; every encoded value is 0, 1 or 2), then streams the retained columns straight into the data section.
+func streamOneHotNpy(path, genoPath, indPath, snpPath string, nInds, nCols, colsPerSnp int, keep [][]bool, encode func([]byte, MissingPolicy) []uint8, missingPolicy MissingPolicy) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := out.Write(npyHeader(nInds, nCols)); err != nil {
+		return err
+	}
+	dataOffset, err := out.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	return streamOneHotColumns(out, dataOffset, genoPath, indPath, snpPath, nInds, nCols, colsPerSnp, keep, encode, missingPolicy)
+}
+
+const oneHotMagic = "PAMOH1\n"
+
+// streamOneHotCustom writes a small self-describing binary header (magic,
+// individual and column counts, each column's SNP name and class), then
+// streams the retained columns straight into the data section.
+func streamOneHotCustom(path, genoPath, indPath, snpPath string, nInds int, columns []oneHotColumn, colsPerSnp int, keep [][]bool, encode func([]byte, MissingPolicy) []uint8, missingPolicy MissingPolicy) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := out.WriteString(oneHotMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(out, binary.LittleEndian, uint32(nInds)); err != nil {
+		return err
+	}
+	if err := binary.Write(out, binary.LittleEndian, uint32(len(columns))); err != nil {
+		return err
+	}
+
+	for _, col := range columns {
+		if err := binary.Write(out, binary.LittleEndian, uint16(len(col.SnpName))); err != nil {
+			return err
+		}
+		if _, err := out.WriteString(col.SnpName); err != nil {
+			return err
+		}
+		if err := binary.Write(out, binary.LittleEndian, uint16(len(col.Class))); err != nil {
+			return err
+		}
+		if _, err := out.WriteString(col.Class); err != nil {
+			return err
+		}
+	}
+
+	dataOffset, err := out.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	return streamOneHotColumns(out, dataOffset, genoPath, indPath, snpPath, nInds, len(columns), colsPerSnp, keep, encode, missingPolicy)
+}
+
+// streamOneHotColumns is ExportOneHot's second pass: it re-scans
+// genoPath/indPath/snpPath, re-encodes only the SNPs with at least one
+// retained column per plan, and writes those columns to out at dataOffset.
+// Like ExportNumpy (chunk0-1), it buffers a block of columns across all
+// individuals and flushes it with one WriteAt per individual, rather than
+// one WriteAt per matrix cell, bounding memory to a block instead of the
+// full output matrix.
+func streamOneHotColumns(out *os.File, dataOffset int64, genoPath, indPath, snpPath string, nInds, nCols, colsPerSnp int, keep [][]bool, encode func([]byte, MissingPolicy) []uint8, missingPolicy MissingPolicy) error {
+	blockCols := nCols
+	if nInds > 0 {
+		if bc := numpyExportBlockBytes / nInds; bc < blockCols {
+			if bc < 1 {
+				bc = 1
+			}
+			blockCols = bc
+		}
+	}
+
+	block := make([]byte, nInds*blockCols)
+	col, globalCol := 0, 0
+
+	flush := func() error {
+		if col == 0 {
+			return nil
+		}
+		rowStartCol := globalCol - col
+		for indIndex := 0; indIndex < nInds; indIndex++ {
+			offset := dataOffset + int64(indIndex*nCols+rowStartCol)
+			segment := block[indIndex*blockCols : indIndex*blockCols+col]
+			if _, err := out.WriteAt(segment, offset); err != nil {
+				return err
+			}
+		}
+		col = 0
+		return nil
+	}
+
+	snpIndex := 0
+	err := ProcessGenoRowsSeq(genoPath, indPath, snpPath, func(genoRow []byte, snp Snp, inds []Ind) error {
+		snpKeep := keep[snpIndex]
+		snpIndex++
+
+		anyKept := false
+		for _, k := range snpKeep {
+			anyKept = anyKept || k
+		}
+		if !anyKept {
+			return nil
+		}
+
+		encoded := encode(genoRow, missingPolicy)
+		for c := 0; c < colsPerSnp; c++ {
+			if !snpKeep[c] {
+				continue
+			}
+			for i := 0; i < nInds; i++ {
+				block[i*blockCols+col] = encoded[colsPerSnp*i+c]
+			}
+			col++
+			globalCol++
+			if col == blockCols {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return flush()
+}
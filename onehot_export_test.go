@@ -0,0 +1,135 @@
+package packedancestrymap
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportOneHotNpy(t *testing.T) {
+	dir := t.TempDir()
+	inds := []Ind{
+		{SampleID: "S1", Gender: "M", Label: "Pop1"},
+		{SampleID: "S2", Gender: "F", Label: "Pop2"},
+		{SampleID: "S3", Gender: "U", Label: "Pop1"},
+		{SampleID: "S4", Gender: "U", Label: "Pop1"},
+	}
+	snps := []Snp{
+		{Name: "rsCommon", Chromosome: 1, PhysicalPos: 1000, Ref: 'A', Alt: 'G'},
+		{Name: "rsRare", Chromosome: 1, PhysicalPos: 2000, Ref: 'C', Alt: 'T'},
+	}
+	rows := [][]byte{
+		{0, 1, 1, 2}, // balanced: minor allele frequency well above a low threshold
+		{0, 0, 0, 1}, // rare alt allele: low minor allele frequency
+	}
+	geno, ind, snp := writeExportTestTriple(t, dir, inds, snps, rows)
+
+	outPath := filepath.Join(dir, "onehot.npy")
+	opts := OneHotOptions{MinFrequency: 0.2}
+	if err := ExportOneHot(geno, ind, snp, outPath, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	gotRows, gotCols, data := readNpyInt8(t, outPath)
+	if gotRows != len(inds) {
+		t.Fatalf("rows = %d, want %d", gotRows, len(inds))
+	}
+
+	// rsRare's minor allele frequency (1 alt / 8 alleles = 0.125) is below
+	// the 0.2 threshold, so the whole variant - all three of its columns -
+	// must be dropped, leaving only rsCommon's hom-ref/het/hom-alt columns.
+	wantCols := 3
+	if gotCols != wantCols {
+		t.Fatalf("cols = %d, want %d (rsRare should be filtered out entirely)", gotCols, wantCols)
+	}
+
+	// rsCommon = {0, 1, 1, 2}: S1 hom-ref, S2/S3 het, S4 hom-alt.
+	want := []byte{
+		1, 0, 0, // S1: hom-ref
+		0, 1, 0, // S2: het
+		0, 1, 0, // S3: het
+		0, 0, 1, // S4: hom-alt
+	}
+	if !bytesEqual(data, want) {
+		t.Errorf("data = %v, want %v", data, want)
+	}
+}
+
+func TestExportOneHotCustom(t *testing.T) {
+	dir := t.TempDir()
+	inds := []Ind{
+		{SampleID: "S1", Gender: "M", Label: "Pop1"},
+		{SampleID: "S2", Gender: "F", Label: "Pop2"},
+	}
+	snps := []Snp{
+		{Name: "rs1", Chromosome: 1, PhysicalPos: 1000, Ref: 'A', Alt: 'G'},
+	}
+	rows := [][]byte{
+		{0, 2},
+	}
+	geno, ind, snp := writeExportTestTriple(t, dir, inds, snps, rows)
+
+	outPath := filepath.Join(dir, "onehot.bin")
+	if err := ExportOneHot(geno, ind, snp, outPath, OneHotOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(oneHotMagic))
+	if _, err := file.Read(magic); err != nil {
+		t.Fatal(err)
+	}
+	if string(magic) != oneHotMagic {
+		t.Fatalf("magic = %q, want %q", magic, oneHotMagic)
+	}
+
+	var nInds, nCols uint32
+	if err := binary.Read(file, binary.LittleEndian, &nInds); err != nil {
+		t.Fatal(err)
+	}
+	if err := binary.Read(file, binary.LittleEndian, &nCols); err != nil {
+		t.Fatal(err)
+	}
+	if int(nInds) != len(inds) {
+		t.Errorf("nInds = %d, want %d", nInds, len(inds))
+	}
+	// hom-ref (S1) and hom-alt (S2) columns survive; het is all-zero and dropped.
+	if int(nCols) != 2 {
+		t.Fatalf("nCols = %d, want 2", nCols)
+	}
+
+	for c := 0; c < int(nCols); c++ {
+		var nameLen uint16
+		if err := binary.Read(file, binary.LittleEndian, &nameLen); err != nil {
+			t.Fatal(err)
+		}
+		name := make([]byte, nameLen)
+		if _, err := file.Read(name); err != nil {
+			t.Fatal(err)
+		}
+		var classLen uint16
+		if err := binary.Read(file, binary.LittleEndian, &classLen); err != nil {
+			t.Fatal(err)
+		}
+		class := make([]byte, classLen)
+		if _, err := file.Read(class); err != nil {
+			t.Fatal(err)
+		}
+		if string(name) != "rs1" {
+			t.Errorf("column %d name = %q, want rs1", c, name)
+		}
+	}
+
+	row := make([]byte, nCols)
+	for i := 0; i < int(nInds); i++ {
+		if _, err := file.Read(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package packedancestrymap
+
+import "testing"
+
+func TestOneHotEncode(t *testing.T) {
+	genoRow := []byte{0, 1, 2, 3}
+
+	got := OneHotEncode(genoRow, MissingAllZero)
+	want := []uint8{
+		1, 0, 0, // hom-ref
+		0, 1, 0, // het
+		0, 0, 1, // hom-alt
+		0, 0, 0, // missing, dropped
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("OneHotEncode(MissingAllZero) = %v, want %v", got, want)
+		}
+	}
+
+	got = OneHotEncode(genoRow, MissingAsHet)
+	if got[3*3+1] != 1 {
+		t.Errorf("OneHotEncode(MissingAsHet) did not mark the missing call as het: %v", got)
+	}
+}
+
+func TestOneHotAllelicEncode(t *testing.T) {
+	genoRow := []byte{0, 1, 2}
+
+	got := OneHotAllelicEncode(genoRow, MissingAllZero)
+	want := []uint8{2, 0, 1, 1, 0, 2}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("OneHotAllelicEncode = %v, want %v", got, want)
+		}
+	}
+}
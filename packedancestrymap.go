@@ -176,6 +176,69 @@ func ProcessGenoRows(genoPath, indPath, snpPath string, processFunc func(genoRow
 	return nil
 }
 
+// ProcessGenoRowsSeq is like ProcessGenoRows but decodes SNP rows in file
+// order on the calling goroutine instead of fanning out, and lets processFunc
+// abort the scan by returning an error. Callers that need row index stability
+// (e.g. writing each SNP into a fixed matrix column) should use this instead.
+func ProcessGenoRowsSeq(genoPath, indPath, snpPath string, processFunc func(genoRow []byte, snp Snp, inds []Ind) error) error {
+	ok, err := Calcishash(genoPath, indPath, snpPath)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("Hash is not ok")
+	}
+
+	snps, err := ReadSnpFile(snpPath)
+	if err != nil {
+		return err
+	}
+
+	inds, err := ReadIndFile(indPath)
+	if err != nil {
+		return err
+	}
+
+	genoFile, err := os.Open(genoPath)
+	if err != nil {
+		return err
+	}
+	defer genoFile.Close()
+
+	genoReader := bufio.NewReader(genoFile)
+	chunkSize := int(math.Ceil(float64(len(inds)) / 4))
+
+	// Why 48? See original EIG/src/mcio.c
+	genoChunkSize := chunkSize
+	if genoChunkSize < 48 {
+		genoChunkSize = 48
+	}
+
+	// Skip header
+	rchunk := make([]byte, genoChunkSize)
+	genoReader.Read(rchunk)
+
+	for snpIndex := 0; snpIndex < len(snps); snpIndex++ {
+		genoReader.Read(rchunk)
+
+		genoRow := make([]byte, len(inds))
+		for indIndex := 0; indIndex < len(inds); indIndex++ {
+			byteOffset := int(math.Floor(float64(indIndex) / 4))
+			bitOffset := uint8(indIndex%4) * 2
+			b := rchunk[byteOffset]
+			genotype := (b >> bitOffset) & 3
+			genoRow[indIndex] = genotype
+		}
+
+		if err := processFunc(genoRow, snps[snpIndex], inds); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // HashIt takes a string as argument and calculates hash for it
 func HashIt(str string) uint32 {
 	var hash, length uint32 = 0, uint32(len(str))
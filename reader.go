@@ -0,0 +1,172 @@
+package packedancestrymap
+
+import (
+	"errors"
+	"math"
+	"os"
+)
+
+var errShortRead = errors.New("short read past end of geno file")
+
+// readerBacking abstracts how Reader pulls raw bytes out of the .geno file,
+// so the random-access logic below doesn't care whether it's backed by an
+// mmap or a plain pread-style ReadAt.
+type readerBacking interface {
+	ReadAt(buf []byte, offset int64) error
+	Close() error
+}
+
+// Reader provides random access to a .geno file's SNP rows and individual
+// columns without re-scanning from the start for every request, unlike
+// ProcessGenoRows. It keeps the .geno file mapped (or open, on platforms
+// without mmap support) for the lifetime of the Reader, so long-running
+// callers can serve many requests against one Reader instead of reopening
+// and reparsing the triple per call.
+type Reader struct {
+	backing readerBacking
+
+	Snps []Snp
+	Inds []Ind
+
+	stride     int // bytes per packed SNP row, including the header row
+	headerSize int
+}
+
+// NewReader validates the .geno/.ind/.snp triple's hash, loads the .ind and
+// .snp metadata, and opens (mmapping where supported) the .geno file for
+// random access.
+func NewReader(genoPath, indPath, snpPath string) (*Reader, error) {
+	ok, err := Calcishash(genoPath, indPath, snpPath)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("Hash is not ok")
+	}
+
+	snps, err := ReadSnpFile(snpPath)
+	if err != nil {
+		return nil, err
+	}
+	inds, err := ReadIndFile(indPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stride := int(math.Ceil(float64(len(inds)) / 4))
+	// Why 48? See original EIG/src/mcio.c
+	if stride < 48 {
+		stride = 48
+	}
+
+	backing, err := openReaderBacking(genoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		backing:    backing,
+		Snps:       snps,
+		Inds:       inds,
+		stride:     stride,
+		headerSize: stride,
+	}, nil
+}
+
+// Close releases the Reader's backing resources (unmapping, if mmapped).
+func (r *Reader) Close() error {
+	return r.backing.Close()
+}
+
+// ReadSnp decodes the genotype row (one value per individual, 0/1/2/3) for
+// the SNP at index in O(1) via a direct seek, rather than scanning every
+// preceding row.
+func (r *Reader) ReadSnp(index int) ([]byte, error) {
+	if index < 0 || index >= len(r.Snps) {
+		return nil, errors.New("snp index out of range")
+	}
+
+	chunk := make([]byte, r.stride)
+	offset := int64(r.headerSize) + int64(index)*int64(r.stride)
+	if err := r.backing.ReadAt(chunk, offset); err != nil {
+		return nil, err
+	}
+
+	return unpackGenoChunk(chunk, len(r.Inds)), nil
+}
+
+// ReadSnpRange decodes the genotype rows for SNPs [lo, hi).
+func (r *Reader) ReadSnpRange(lo, hi int) ([][]byte, error) {
+	if lo < 0 || hi > len(r.Snps) || lo > hi {
+		return nil, errors.New("snp range out of bounds")
+	}
+
+	rows := make([][]byte, 0, hi-lo)
+	for i := lo; i < hi; i++ {
+		row, err := r.ReadSnp(i)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ReadIndividual decodes one individual's genotype across every SNP,
+// slicing the same column out of each packed row in turn.
+func (r *Reader) ReadIndividual(index int) ([]byte, error) {
+	if index < 0 || index >= len(r.Inds) {
+		return nil, errors.New("individual index out of range")
+	}
+
+	byteOffset := index / 4
+	bitOffset := uint8(index%4) * 2
+
+	col := make([]byte, len(r.Snps))
+	buf := make([]byte, 1)
+	for snpIndex := range r.Snps {
+		offset := int64(r.headerSize) + int64(snpIndex)*int64(r.stride) + int64(byteOffset)
+		if err := r.backing.ReadAt(buf, offset); err != nil {
+			return nil, err
+		}
+		col[snpIndex] = (buf[0] >> bitOffset) & 3
+	}
+
+	return col, nil
+}
+
+// unpackGenoChunk decodes a raw 4-per-byte packed row into one genotype
+// value (0/1/2/3) per individual.
+func unpackGenoChunk(chunk []byte, nInds int) []byte {
+	row := make([]byte, nInds)
+	for indIndex := 0; indIndex < nInds; indIndex++ {
+		byteOffset := indIndex / 4
+		bitOffset := uint8(indIndex%4) * 2
+		row[indIndex] = (chunk[byteOffset] >> bitOffset) & 3
+	}
+	return row
+}
+
+// preadBacking implements readerBacking with plain file reads (pread-style
+// ReadAt), for platforms where mmap isn't available.
+type preadBacking struct {
+	file *os.File
+}
+
+func newPreadBacking(path string) (readerBacking, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &preadBacking{file: file}, nil
+}
+
+func (b *preadBacking) ReadAt(buf []byte, offset int64) error {
+	_, err := b.file.ReadAt(buf, offset)
+	return err
+}
+
+func (b *preadBacking) Close() error {
+	return b.file.Close()
+}
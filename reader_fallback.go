@@ -0,0 +1,9 @@
+//go:build !unix
+
+package packedancestrymap
+
+// openReaderBacking falls back to plain pread-style reads on platforms
+// without the mmap support reader_mmap_unix.go relies on.
+func openReaderBacking(path string) (readerBacking, error) {
+	return newPreadBacking(path)
+}
@@ -0,0 +1,51 @@
+//go:build unix
+
+package packedancestrymap
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapBacking implements readerBacking over a memory-mapped .geno file.
+type mmapBacking struct {
+	file *os.File
+	data []byte
+}
+
+func openReaderBacking(path string) (readerBacking, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &mmapBacking{file: file, data: data}, nil
+}
+
+func (b *mmapBacking) ReadAt(buf []byte, offset int64) error {
+	if offset < 0 || offset+int64(len(buf)) > int64(len(b.data)) {
+		return errShortRead
+	}
+	copy(buf, b.data[offset:])
+	return nil
+}
+
+func (b *mmapBacking) Close() error {
+	if err := syscall.Munmap(b.data); err != nil {
+		b.file.Close()
+		return err
+	}
+	return b.file.Close()
+}
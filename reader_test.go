@@ -0,0 +1,165 @@
+package packedancestrymap
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// writeTestTriple packs genotypes (rows = SNPs, values 0-3) into a minimal
+// .geno/.ind/.snp triple with a matching header hash, for exercising Reader
+// without needing real EIGENSTRAT fixtures.
+func writeTestTriple(t *testing.T, dir string, nInds int, rows [][]byte) (geno, ind, snp string) {
+	t.Helper()
+
+	ind = filepath.Join(dir, "t.ind")
+	indLines := ""
+	for i := 0; i < nInds; i++ {
+		indLines += "Sample" + strconv.Itoa(i) + " U Pop\n"
+	}
+	if err := os.WriteFile(ind, []byte(indLines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snp = filepath.Join(dir, "t.snp")
+	snpLines := ""
+	for i := range rows {
+		snpLines += "rs" + strconv.Itoa(i) + " 1 0.0 " + strconv.Itoa(1000+i) + " A G\n"
+	}
+	if err := os.WriteFile(snp, []byte(snpLines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	indHash, err := HashFileFirstColumn(ind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snpHash, err := HashFileFirstColumn(snp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stride := (nInds + 3) / 4
+	if stride < 48 {
+		stride = 48
+	}
+
+	header := make([]byte, stride)
+	copy(header, []byte("GENO "+strconv.Itoa(nInds)+" "+strconv.Itoa(len(rows))+" "+
+		strconv.FormatInt(int64(indHash), 16)+" "+strconv.FormatInt(int64(snpHash), 16)))
+
+	geno = filepath.Join(dir, "t.geno")
+	file, err := os.Create(geno)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(header); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, row := range rows {
+		packed := make([]byte, stride)
+		for i, g := range row {
+			packed[i/4] |= g << (uint(i%4) * 2)
+		}
+		if _, err := file.Write(packed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return geno, ind, snp
+}
+
+func TestReaderRandomAccess(t *testing.T) {
+	dir := t.TempDir()
+	rows := [][]byte{
+		{0, 1, 2, 3},
+		{1, 1, 0, 2},
+		{2, 0, 1, 3},
+	}
+	geno, ind, snp := writeTestTriple(t, dir, 4, rows)
+
+	r, err := NewReader(geno, ind, snp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for i, want := range rows {
+		got, err := r.ReadSnp(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytesEqual(got, want) {
+			t.Errorf("ReadSnp(%d) = %v, want %v", i, got, want)
+		}
+	}
+
+	rangeRows, err := r.ReadSnpRange(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rangeRows) != 2 || !bytesEqual(rangeRows[0], rows[1]) || !bytesEqual(rangeRows[1], rows[2]) {
+		t.Errorf("ReadSnpRange(1, 3) = %v, want %v", rangeRows, rows[1:3])
+	}
+
+	col, err := r.ReadIndividual(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{rows[0][2], rows[1][2], rows[2][2]}
+	if !bytesEqual(col, want) {
+		t.Errorf("ReadIndividual(2) = %v, want %v", col, want)
+	}
+}
+
+// TestReaderShortGenoFile covers a .geno file truncated after the .ind/.snp
+// files were written (so Calcishash's header-hash check still passes, since
+// it only checks the header string against the .ind/.snp hashes, not the
+// geno body's length against the snp count). Reads past the truncated body
+// must return an error rather than panic with a slice-bounds-out-of-range.
+func TestReaderShortGenoFile(t *testing.T) {
+	dir := t.TempDir()
+	rows := [][]byte{
+		{0, 1, 2, 3},
+		{1, 1, 0, 2},
+		{2, 0, 1, 3},
+	}
+	geno, ind, snp := writeTestTriple(t, dir, 4, rows)
+
+	stride := int64(48) // nInds=4 -> ceil(4/4)=1, floored up to the 48-byte minimum.
+	if err := os.Truncate(geno, 2*stride); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(geno, ind, snp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if _, err := r.ReadSnp(0); err != nil {
+		t.Errorf("ReadSnp(0) should still succeed within the truncated body: %v", err)
+	}
+	if _, err := r.ReadSnp(2); err == nil {
+		t.Error("ReadSnp(2) past the truncated body should return an error, not succeed")
+	}
+	if _, err := r.ReadIndividual(0); err == nil {
+		t.Error("ReadIndividual(0) past the truncated body should return an error, not succeed")
+	}
+}
+
+func bytesEqual(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
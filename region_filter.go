@@ -0,0 +1,187 @@
+package packedancestrymap
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// interval is a half-open [Start, End) base-pair range on one chromosome.
+type interval struct {
+	Start, End uint32
+}
+
+// chromIntervals is a chromosome's intervals sorted by Start, alongside a
+// running maximum of End so Contains can prune a backward scan once no
+// earlier interval could possibly reach the query position.
+type chromIntervals struct {
+	intervals []interval
+	maxEnd    []uint32
+}
+
+// RegionFilter restricts SNP iteration to the intervals loaded from a BED
+// file, keyed by EIGENSTRAT chromosome code.
+type RegionFilter struct {
+	byChrom map[uint8]chromIntervals
+}
+
+// eigenstratChromNames maps EIGENSTRAT chromosome codes to the BED chromosome
+// names they may appear under, both with and without the "chr" prefix.
+var eigenstratChromNames = map[uint8][]string{
+	23: {"23", "chr23", "X", "chrX"},
+	24: {"24", "chr24", "Y", "chrY"},
+	90: {"90", "chr90", "chrMT", "MT", "chrM", "M"},
+	91: {"91", "chr91", "chrXY", "XY"},
+}
+
+// bedChromToEigenstrat inverts eigenstratChromNames plus the plain numeric
+// chromosomes (1-22, "chr1".."chr22") shared by both naming schemes.
+func bedChromToEigenstrat(name string) (uint8, bool) {
+	for code, names := range eigenstratChromNames {
+		for _, n := range names {
+			if strings.EqualFold(n, name) {
+				return code, true
+			}
+		}
+	}
+
+	trimmed := strings.TrimPrefix(strings.ToLower(name), "chr")
+	n, err := strconv.Atoi(trimmed)
+	if err != nil || n < 1 || n > 22 {
+		return 0, false
+	}
+
+	return uint8(n), true
+}
+
+// NewRegionFilter loads a BED file ("chrom start end", tab- or space-
+// separated) and builds a RegionFilter. Each interval is padded by expand
+// bases on both sides (clamped to zero at the lower bound).
+func NewRegionFilter(bedPath string, expand uint32) (*RegionFilter, error) {
+	file, err := os.Open(bedPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	byChrom := make(map[uint8][]interval)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "track") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+
+		code, ok := bedChromToEigenstrat(fields[0])
+		if !ok {
+			continue
+		}
+
+		start, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		end, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+
+		iv := interval{Start: uint32(start), End: uint32(end)}
+		if expand > 0 {
+			if iv.Start > expand {
+				iv.Start -= expand
+			} else {
+				iv.Start = 0
+			}
+			iv.End += expand
+		}
+
+		byChrom[code] = append(byChrom[code], iv)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	rf := &RegionFilter{byChrom: make(map[uint8]chromIntervals, len(byChrom))}
+	for code, intervals := range byChrom {
+		sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start < intervals[j].Start })
+
+		maxEnd := make([]uint32, len(intervals))
+		running := uint32(0)
+		for i, iv := range intervals {
+			if iv.End > running {
+				running = iv.End
+			}
+			maxEnd[i] = running
+		}
+
+		rf.byChrom[code] = chromIntervals{intervals: intervals, maxEnd: maxEnd}
+	}
+
+	return rf, nil
+}
+
+// Contains reports whether (chromosome, pos) falls within one of the loaded
+// intervals. It binary-searches for the last interval starting at or before
+// pos, then scans backward through any overlapping intervals, pruning as
+// soon as the running maximum End proves no earlier interval can reach pos.
+func (rf *RegionFilter) Contains(chromosome uint8, pos uint32) bool {
+	ci, ok := rf.byChrom[chromosome]
+	if !ok || len(ci.intervals) == 0 {
+		return false
+	}
+
+	i := sort.Search(len(ci.intervals), func(i int) bool { return ci.intervals[i].Start > pos })
+	for j := i - 1; j >= 0; j-- {
+		if ci.maxEnd[j] <= pos {
+			break
+		}
+		if pos >= ci.intervals[j].Start && pos < ci.intervals[j].End {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FilterSnps returns the subset of snps that intersect rf.
+func FilterSnps(snps []Snp, rf *RegionFilter) []Snp {
+	filtered := make([]Snp, 0, len(snps))
+	for _, snp := range snps {
+		if rf.Contains(snp.Chromosome, snp.PhysicalPos) {
+			filtered = append(filtered, snp)
+		}
+	}
+	return filtered
+}
+
+// GenoOptions configures ProcessGenoRowsFiltered.
+type GenoOptions struct {
+	// Region restricts callbacks to SNPs intersecting these intervals.
+	// A nil Region processes every SNP.
+	Region *RegionFilter
+}
+
+// ProcessGenoRowsFiltered is ProcessGenoRows with an additional GenoOptions,
+// skipping the processFunc callback for any SNP outside opts.Region when one
+// is set.
+func ProcessGenoRowsFiltered(genoPath, indPath, snpPath string, opts GenoOptions, processFunc func(genoRow []byte, snp Snp, inds []Ind)) error {
+	if opts.Region == nil {
+		return ProcessGenoRows(genoPath, indPath, snpPath, processFunc)
+	}
+
+	return ProcessGenoRows(genoPath, indPath, snpPath, func(genoRow []byte, snp Snp, inds []Ind) {
+		if !opts.Region.Contains(snp.Chromosome, snp.PhysicalPos) {
+			return
+		}
+		processFunc(genoRow, snp, inds)
+	})
+}
@@ -0,0 +1,62 @@
+package packedancestrymap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegionFilter(t *testing.T) {
+	bed := "1\t1000\t2000\nchrX\t500\t600\n"
+	path := filepath.Join(t.TempDir(), "regions.bed")
+	if err := os.WriteFile(path, []byte(bed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rf, err := NewRegionFilter(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		chrom uint8
+		pos   uint32
+		want  bool
+	}{
+		{1, 1500, true},
+		{1, 990, true}, // padded by expand=10
+		{1, 2500, false},
+		{23, 550, true}, // chrX -> EIGENSTRAT code 23
+		{23, 100, false},
+	}
+
+	for _, c := range cases {
+		got := rf.Contains(c.chrom, c.pos)
+		if got != c.want {
+			t.Errorf("Contains(%d, %d) = %v, want %v", c.chrom, c.pos, got, c.want)
+		}
+	}
+}
+
+func TestFilterSnps(t *testing.T) {
+	bed := "1\t1000\t2000\n"
+	path := filepath.Join(t.TempDir(), "regions.bed")
+	if err := os.WriteFile(path, []byte(bed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rf, err := NewRegionFilter(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snps := []Snp{
+		{Name: "in", Chromosome: 1, PhysicalPos: 1500},
+		{Name: "out", Chromosome: 1, PhysicalPos: 5000},
+	}
+
+	filtered := FilterSnps(snps, rf)
+	if len(filtered) != 1 || filtered[0].Name != "in" {
+		t.Errorf("FilterSnps = %+v, want only %q", filtered, "in")
+	}
+}
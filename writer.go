@@ -0,0 +1,177 @@
+package packedancestrymap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+)
+
+// Writer emits a valid EIGENSTRAT .geno/.ind/.snp triple, one packed SNP row
+// at a time. This is the write-side counterpart to ProcessGenoRows/Reader,
+// letting callers transform, subset or merge a dataset and re-emit it in a
+// form Calcishash will accept.
+type Writer struct {
+	genoFile *os.File
+	genoBuf  *bufio.Writer
+
+	inds []Ind
+	snps []Snp
+
+	stride     int
+	rowsDone   int
+	headerLine string
+
+	indPath, snpPath string
+}
+
+// NewWriter creates the genoPath, indPath and snpPath files, writes the .ind and .snp
+// files immediately (since their contents are known up front), and prepares
+// the .geno file for WriteGenoRow. inds and snps determine the triple's
+// dimensions and hashes: the .geno header is built from nInds, len(snps),
+// and the hex HashFileFirstColumn hashes of the .ind and .snp files, so a
+// Calcishash check against the finished files succeeds.
+func NewWriter(genoPath, indPath, snpPath string, inds []Ind, snps []Snp) (*Writer, error) {
+	if err := writeIndFile(indPath, inds); err != nil {
+		return nil, err
+	}
+	if err := writeSnpFile(snpPath, snps); err != nil {
+		return nil, err
+	}
+
+	indHash, err := HashFileFirstColumn(indPath)
+	if err != nil {
+		return nil, err
+	}
+	snpHash, err := HashFileFirstColumn(snpPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stride := int(math.Ceil(float64(len(inds)) / 4))
+	// Why 48? See original EIG/src/mcio.c
+	if stride < 48 {
+		stride = 48
+	}
+
+	header := fmt.Sprintf("GENO %d %d %s %s",
+		len(inds), len(snps),
+		strconv.FormatInt(int64(indHash), 16),
+		strconv.FormatInt(int64(snpHash), 16))
+
+	genoFile, err := os.Create(genoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Writer{
+		genoFile:   genoFile,
+		genoBuf:    bufio.NewWriter(genoFile),
+		inds:       inds,
+		snps:       snps,
+		stride:     stride,
+		headerLine: header,
+		indPath:    indPath,
+		snpPath:    snpPath,
+	}
+
+	// Calcishash reads the header back as a single newline-terminated
+	// line, so the line break must live inside the fixed-width header
+	// block rather than relying on writeRow's zero padding.
+	headerBlock := make([]byte, stride)
+	for i := range headerBlock {
+		headerBlock[i] = ' '
+	}
+	copy(headerBlock, header)
+	headerBlock[len(header)] = '\n'
+
+	if err := w.writeRow(headerBlock); err != nil {
+		genoFile.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// WriteGenoRow packs one SNP's genotype values (one byte per individual,
+// 0/1/2/3) 4-per-byte little-endian within each byte, pads the row to the
+// triple's stride, and appends it to the .geno file. Rows must be written in
+// the same order as the snps passed to NewWriter.
+func (w *Writer) WriteGenoRow(row []byte) error {
+	if len(row) != len(w.inds) {
+		return errors.New("geno row length does not match number of individuals")
+	}
+	if w.rowsDone >= len(w.snps) {
+		return errors.New("more geno rows written than snps passed to NewWriter")
+	}
+
+	packed := make([]byte, w.stride)
+	for i, g := range row {
+		packed[i/4] |= g << (uint(i%4) * 2)
+	}
+
+	if err := w.writeRow(packed); err != nil {
+		return err
+	}
+
+	w.rowsDone++
+	return nil
+}
+
+func (w *Writer) writeRow(row []byte) error {
+	padded := make([]byte, w.stride)
+	copy(padded, row)
+	_, err := w.genoBuf.Write(padded)
+	return err
+}
+
+// Close flushes and closes the .geno file. It returns an error if fewer rows
+// were written than the snps passed to NewWriter, since that would leave a
+// .geno file with an inconsistent row count.
+func (w *Writer) Close() error {
+	defer w.genoFile.Close()
+
+	if w.rowsDone != len(w.snps) {
+		return fmt.Errorf("wrote %d geno rows, want %d", w.rowsDone, len(w.snps))
+	}
+
+	return w.genoBuf.Flush()
+}
+
+func writeIndFile(path string, inds []Ind) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	for _, ind := range inds {
+		if _, err := fmt.Fprintf(w, "%s %s %s\n", ind.SampleID, ind.Gender, ind.Label); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func writeSnpFile(path string, snps []Snp) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	for _, snp := range snps {
+		_, err := fmt.Fprintf(w, "%s %d %.6f %d %c %c\n",
+			snp.Name, snp.Chromosome, snp.GeneticPos, snp.PhysicalPos, snp.Ref, snp.Alt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,92 @@
+package packedancestrymap
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	geno := filepath.Join(dir, "out.geno")
+	ind := filepath.Join(dir, "out.ind")
+	snp := filepath.Join(dir, "out.snp")
+
+	inds := []Ind{
+		{SampleID: "S1", Gender: "M", Label: "Pop1"},
+		{SampleID: "S2", Gender: "F", Label: "Pop2"},
+		{SampleID: "S3", Gender: "U", Label: "Pop1"},
+	}
+	snps := []Snp{
+		{Name: "rs1", Chromosome: 1, GeneticPos: 0.1, PhysicalPos: 1000, Ref: 'A', Alt: 'G'},
+		{Name: "rs2", Chromosome: 2, GeneticPos: 0.2, PhysicalPos: 2000, Ref: 'C', Alt: 'T'},
+	}
+	rows := [][]byte{
+		{0, 1, 2},
+		{2, 1, 0},
+	}
+
+	w, err := NewWriter(geno, ind, snp, inds, snps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.WriteGenoRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := Calcishash(geno, ind, snp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("Calcishash returned false for a freshly written triple")
+	}
+
+	var got [][]byte
+	err = ProcessGenoRowsSeq(geno, ind, snp, func(genoRow []byte, snp Snp, inds []Ind) error {
+		row := make([]byte, len(genoRow))
+		copy(row, genoRow)
+		got = append(got, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(rows) {
+		t.Fatalf("got %d rows, want %d", len(got), len(rows))
+	}
+	for i := range rows {
+		if !bytesEqual(got[i], rows[i]) {
+			t.Errorf("row %d = %v, want %v", i, got[i], rows[i])
+		}
+	}
+}
+
+func TestWriterCloseRequiresAllRows(t *testing.T) {
+	dir := t.TempDir()
+	geno := filepath.Join(dir, "out.geno")
+	ind := filepath.Join(dir, "out.ind")
+	snp := filepath.Join(dir, "out.snp")
+
+	inds := []Ind{{SampleID: "S1", Gender: "M", Label: "Pop1"}}
+	snps := []Snp{
+		{Name: "rs1", Chromosome: 1, PhysicalPos: 1000, Ref: 'A', Alt: 'G'},
+		{Name: "rs2", Chromosome: 1, PhysicalPos: 2000, Ref: 'A', Alt: 'G'},
+	}
+
+	w, err := NewWriter(geno, ind, snp, inds, snps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteGenoRow([]byte{0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err == nil {
+		t.Error("Close should fail when fewer rows were written than snps")
+	}
+}